@@ -0,0 +1,92 @@
+/*
+Package prometheus adapts rediss.Metrics onto Prometheus collectors, so
+callers can plug in observability without forcing a Prometheus dependency
+onto the core rediss package.
+
+	reg := prometheus.NewRegistry()
+	m := rsprom.NewMetrics(reg, "mymaster")
+	s := rediss.NewWithOptions(seeds, "mymaster", size, retryDelay, resyncDelay, rediss.Options{
+		Metrics: m,
+	})
+
+*/
+package prometheus
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/richard-lyman/rediss"
+)
+
+// stateValues maps SPool.State to the sentinel_state gauge value.
+var stateValues = map[string]float64{
+	"Creating":      0,
+	"Bootstrapping": 1,
+	"Resetting":     2,
+	"Healthy":       3,
+}
+
+// Metrics implements rediss.Metrics with Prometheus collectors.
+type Metrics struct {
+	failoverTotal prometheus.Counter
+	resetDuration prometheus.Histogram
+	dialErrors    prometheus.Counter
+	acquireWait   prometheus.Histogram
+	commandsTotal *prometheus.CounterVec
+	state         prometheus.Gauge
+}
+
+// NewMetrics builds a Metrics labeled with masterName and registers its
+// collectors with reg.
+func NewMetrics(reg prometheus.Registerer, masterName string) *Metrics {
+	labels := prometheus.Labels{"master_name": masterName}
+	m := &Metrics{
+		failoverTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:        "sentinel_failover_total",
+			Help:        "Total number of sentinel-driven master failovers observed.",
+			ConstLabels: labels,
+		}),
+		resetDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:        "sentinel_reset_duration_seconds",
+			Help:        "Time spent recovering a master after a failover.",
+			ConstLabels: labels,
+		}),
+		dialErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:        "pool_dial_errors_total",
+			Help:        "Total number of failed dials to the master or a replica.",
+			ConstLabels: labels,
+		}),
+		acquireWait: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:        "pool_acquire_wait_seconds",
+			Help:        "Time spent waiting to acquire a pooled connection.",
+			ConstLabels: labels,
+		}),
+		commandsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "commands_total",
+			Help:        "Total number of commands run, by status.",
+			ConstLabels: labels,
+		}, []string{"status"}),
+		state: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "sentinel_state",
+			Help:        "Current SPool state: 0=Creating, 1=Bootstrapping, 2=Resetting, 3=Healthy.",
+			ConstLabels: labels,
+		}),
+	}
+	reg.MustRegister(m.failoverTotal, m.resetDuration, m.dialErrors, m.acquireWait, m.commandsTotal, m.state)
+	return m
+}
+
+func (m *Metrics) IncSentinelFailoverTotal() { m.failoverTotal.Inc() }
+
+func (m *Metrics) ObserveSentinelResetDuration(d time.Duration) { m.resetDuration.Observe(d.Seconds()) }
+
+func (m *Metrics) IncPoolDialError() { m.dialErrors.Inc() }
+
+func (m *Metrics) ObservePoolAcquireWait(d time.Duration) { m.acquireWait.Observe(d.Seconds()) }
+
+func (m *Metrics) IncCommandsTotal(status string) { m.commandsTotal.WithLabelValues(status).Inc() }
+
+func (m *Metrics) SetState(state string) { m.state.Set(stateValues[state]) }
+
+var _ rediss.Metrics = (*Metrics)(nil)
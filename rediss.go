@@ -48,15 +48,30 @@ One possible use is as follows:
 package rediss
 
 import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"github.com/richard-lyman/redisb"
 	"github.com/richard-lyman/redisn"
 	"github.com/richard-lyman/redisp"
+	"io"
 	"net"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+// ErrResetCanceled is returned by resetCtx when the caller's context is
+// done before a new master is found.
+var ErrResetCanceled = errors.New("rediss: reset canceled via context")
+
+// ErrMaxResetAttempts is returned by resetCtx when Options.MaxResetAttempts
+// is exceeded without finding a master.
+var ErrMaxResetAttempts = errors.New("rediss: reset exceeded MaxResetAttempts")
+
 type state string
 
 const (
@@ -66,29 +81,150 @@ const (
 	Healthy       state = "Healthy"
 )
 
+// Options carries the connection-level settings SPool needs to reach
+// sentinels and masters that require AUTH, ACL credentials, or TLS.
+// The zero value of Options preserves the old plaintext, no-auth behavior.
+type Options struct {
+	// SentinelPassword authenticates against sentinels via AUTH. Leave
+	// empty if the sentinels have no `requirepass` set.
+	SentinelPassword string
+	// Username, when set, authenticates against the master with
+	// `HELLO 2 AUTH username password` (Redis 6+ ACL users). When empty,
+	// Password alone is used with a plain AUTH, matching pre-ACL Redis.
+	Username string
+	Password string
+	// DB selects a logical database on the master after connecting.
+	DB int
+	// TLSConfig wraps both sentinel and master connections in TLS when set.
+	TLSConfig *tls.Config
+
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	// SentinelFailThreshold is the number of consecutive probe failures a
+	// known sentinel must accrue before findPreferred drops it from s.hps.
+	// A value <= 0 uses defaultSentinelFailThreshold.
+	SentinelFailThreshold int
+
+	// RouteByCommand, when set, makes Call send commands in
+	// readOnlyCommands to a replica via PDoReplica instead of the master.
+	RouteByCommand bool
+
+	// MaxResetAttempts bounds how many master-search iterations resetCtx
+	// will run before giving up with ErrMaxResetAttempts. A value <= 0
+	// means unbounded, matching the old behavior of reset() spinning until
+	// a master is found.
+	MaxResetAttempts int
+
+	// Logger receives structured events from SPool. A nil Logger installs
+	// a no-op implementation.
+	Logger Logger
+	// Metrics receives counters/gauges/histograms from SPool. A nil
+	// Metrics installs a no-op implementation. See the prometheus
+	// subpackage for a ready-made Prometheus adapter.
+	Metrics Metrics
+}
+
+// Logger is the structured logging hook SPool reports through. Each method
+// takes a message and an even-length list of alternating key, value pairs,
+// mirroring the convention used by most structured loggers (e.g.
+// log/slog, go-kit/log, zap's SugaredLogger).
+type Logger interface {
+	Debug(msg string, kv ...interface{})
+	Info(msg string, kv ...interface{})
+	Warn(msg string, kv ...interface{})
+	Error(msg string, kv ...interface{})
+}
+
+type noopLogger struct{}
+
+func (noopLogger) Debug(msg string, kv ...interface{}) {}
+func (noopLogger) Info(msg string, kv ...interface{})  {}
+func (noopLogger) Warn(msg string, kv ...interface{})  {}
+func (noopLogger) Error(msg string, kv ...interface{}) {}
+
+// Metrics is the observability hook for the counters/gauges/histograms an
+// operator expects from a Sentinel client: sentinel_failover_total,
+// sentinel_reset_duration_seconds, pool_dial_errors_total,
+// pool_acquire_wait_seconds, commands_total{status}, and a State gauge.
+// The prometheus subpackage ships a Prometheus-backed implementation so
+// callers aren't forced to depend on it from the core package.
+type Metrics interface {
+	IncSentinelFailoverTotal()
+	ObserveSentinelResetDuration(d time.Duration)
+	IncPoolDialError()
+	ObservePoolAcquireWait(d time.Duration)
+	IncCommandsTotal(status string)
+	SetState(state string)
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) IncSentinelFailoverTotal()                  {}
+func (noopMetrics) ObserveSentinelResetDuration(time.Duration) {}
+func (noopMetrics) IncPoolDialError()                          {}
+func (noopMetrics) ObservePoolAcquireWait(time.Duration)       {}
+func (noopMetrics) IncCommandsTotal(string)                    {}
+func (noopMetrics) SetState(string)                            {}
+
+// defaultSentinelFailThreshold bounds how long a stale or decommissioned
+// sentinel seed is allowed to keep failing probes before it's evicted from
+// rotation.
+const defaultSentinelFailThreshold = 5
+
 func New(hostPort string, masterName string, size int, retryDelay time.Duration, resyncDelay time.Duration) *SPool {
+	return NewWithOptions([]string{hostPort}, masterName, size, retryDelay, resyncDelay, Options{})
+}
+
+// NewWithOptions accepts the full set of known sentinel seeds, like
+// FailoverOptions.SentinelAddrs, so a client can be seeded with every
+// sentinel in the deployment rather than a single host:port. hostPorts
+// must contain at least one entry.
+func NewWithOptions(hostPorts []string, masterName string, size int, retryDelay time.Duration, resyncDelay time.Duration, opts Options) *SPool {
+	if len(hostPorts) == 0 {
+		panic("rediss: NewWithOptions requires at least one host:port in hostPorts")
+	}
 	s := &SPool{
-		State:      Creating,
-		masterName: masterName,
-		hps:        []string{hostPort},
-		size:       size,
-		retryDelay: retryDelay,
-		p:          hostPort,
-		up:         true,
-		n:          map[string][]redisn.Handler{},
+		State:            Creating,
+		masterName:       masterName,
+		hps:              append([]string{}, hostPorts...),
+		size:             size,
+		retryDelay:       retryDelay,
+		p:                hostPorts[0],
+		up:               true,
+		n:                map[string][]redisn.Handler{},
+		opts:             opts,
+		hpFails:          map[string]int{},
+		replicaPools:     map[string]*redisn.NPool{},
+		replicaConnOwner: map[net.Conn]*redisn.NPool{},
+		logger:           opts.Logger,
+		metrics:          opts.Metrics,
 	}
+	if s.logger == nil {
+		s.logger = noopLogger{}
+	}
+	if s.metrics == nil {
+		s.metrics = noopMetrics{}
+	}
+	s.metrics.SetState(string(s.State))
 	s.bootstrap()
 	s.creator = func() net.Conn {
-		c, err := net.Dial("tcp", s.master)
+		master := s.getMaster()
+		c, err := s.dial(master, false)
 		if err != nil {
 			if strings.HasSuffix(err.Error(), "connection refused") {
-				s.log("Connection error with master at addr: '%s'", s.master)
+				s.log("Connection error with master at addr: '%s'", master)
+				s.logger.Warn("master dial refused, triggering reset", "master", master)
 				s.reset()
-				c, err = net.Dial("tcp", s.master)
+				master = s.getMaster()
+				c, err = s.dial(master, false)
 				if err != nil {
+					s.metrics.IncPoolDialError()
 					panic(fmt.Sprintf("failed to reset: '%s'", err))
 				}
 			} else {
+				s.metrics.IncPoolDialError()
 				panic(err)
 			}
 		}
@@ -113,6 +249,172 @@ type SPool struct {
 	pool        *redisn.NPool
 	logEnabled  bool
 	n           map[string][]redisn.Handler
+	opts        Options
+	hpFails     map[string]int
+	sub         *sentinelSubscriber
+	subMu       sync.Mutex
+
+	// coreMu guards State, master, up, pool, p, hps, and hpFails, all of
+	// which are read from caller goroutines (Get/Put/PDo/...) while being
+	// written concurrently from the sentinel event subscriber and from
+	// resetCtx.
+	coreMu sync.Mutex
+
+	// nMu guards n, which is written by NDo/NUnDo from caller goroutines
+	// and ranged over by resubscribe from the sentinel event subscriber.
+	nMu sync.Mutex
+
+	replicas         []string
+	replicaPools     map[string]*redisn.NPool
+	replicaConnOwner map[net.Conn]*redisn.NPool
+	replicaIdx       uint64
+	replicaMu        sync.Mutex
+
+	logger  Logger
+	metrics Metrics
+}
+
+// setState updates s.State and reports it on the State gauge.
+func (s *SPool) setState(st state) {
+	s.coreMu.Lock()
+	s.State = st
+	s.coreMu.Unlock()
+	s.metrics.SetState(string(st))
+}
+
+// tryBeginReset atomically checks s.State and, if it isn't already
+// Resetting, flips it to Resetting. It reports whether the caller won the
+// race, so two concurrent resetCtx calls can't both proceed.
+func (s *SPool) tryBeginReset() bool {
+	s.coreMu.Lock()
+	defer s.coreMu.Unlock()
+	if s.State == Resetting {
+		return false
+	}
+	s.State = Resetting
+	return true
+}
+
+func (s *SPool) getMaster() string {
+	s.coreMu.Lock()
+	defer s.coreMu.Unlock()
+	return s.master
+}
+
+func (s *SPool) setMaster(addr string) {
+	s.coreMu.Lock()
+	s.master = addr
+	s.coreMu.Unlock()
+}
+
+func (s *SPool) isUp() bool {
+	s.coreMu.Lock()
+	defer s.coreMu.Unlock()
+	return s.up
+}
+
+func (s *SPool) setUp(up bool) {
+	s.coreMu.Lock()
+	s.up = up
+	s.coreMu.Unlock()
+}
+
+func (s *SPool) getPreferred() string {
+	s.coreMu.Lock()
+	defer s.coreMu.Unlock()
+	return s.p
+}
+
+func (s *SPool) getPool() *redisn.NPool {
+	s.coreMu.Lock()
+	defer s.coreMu.Unlock()
+	return s.pool
+}
+
+// getHps returns a copy of s.hps, safe for the caller to range over without
+// holding coreMu.
+func (s *SPool) getHps() []string {
+	s.coreMu.Lock()
+	defer s.coreMu.Unlock()
+	return append([]string{}, s.hps...)
+}
+
+// recordCommand reports a command outcome on the commands_total counter.
+func (s *SPool) recordCommand(err error) {
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	s.metrics.IncCommandsTotal(status)
+}
+
+// dial opens a connection to addr with no context deadline; only the
+// Options timeouts (DialTimeout, and ReadTimeout/WriteTimeout during the
+// AUTH/HELLO/SELECT handshake) bound it.
+func (s *SPool) dial(addr string, forSentinel bool) (net.Conn, error) {
+	return s.dialCtx(context.Background(), addr, forSentinel)
+}
+
+// dialCtx opens a connection to addr, applying the configured dial timeout,
+// TLS, and authentication. ctx bounds the TCP dial only: the TLS handshake
+// and the AUTH/HELLO/SELECT round trips do not watch ctx. forSentinel
+// selects the sentinel credentials (SentinelPassword) over the master
+// credentials (Username/Password/DB), since the two may legitimately
+// differ.
+func (s *SPool) dialCtx(ctx context.Context, addr string, forSentinel bool) (net.Conn, error) {
+	d := net.Dialer{Timeout: s.opts.DialTimeout}
+	c, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if s.opts.TLSConfig != nil {
+		tc := tls.Client(c, s.opts.TLSConfig)
+		if err := tc.Handshake(); err != nil {
+			c.Close()
+			return nil, err
+		}
+		c = tc
+	}
+	if s.opts.ReadTimeout > 0 || s.opts.WriteTimeout > 0 {
+		now := time.Now()
+		if s.opts.WriteTimeout > 0 {
+			c.SetWriteDeadline(now.Add(s.opts.WriteTimeout))
+		}
+		if s.opts.ReadTimeout > 0 {
+			c.SetReadDeadline(now.Add(s.opts.ReadTimeout))
+		}
+		// Cleared before the conn is handed back so a slow AUTH/SELECT
+		// handshake can't leave a stale deadline on a conn that's about to
+		// be pooled and reused for unrelated commands.
+		defer c.SetDeadline(time.Time{})
+	}
+	if forSentinel {
+		if s.opts.SentinelPassword != "" {
+			if _, err := redisb.Do(c, "AUTH", s.opts.SentinelPassword); err != nil {
+				c.Close()
+				return nil, fmt.Errorf("sentinel auth failed: %s", err)
+			}
+		}
+		return c, nil
+	}
+	if s.opts.Username != "" {
+		if _, err := redisb.Do(c, "HELLO", "2", "AUTH", s.opts.Username, s.opts.Password); err != nil {
+			c.Close()
+			return nil, fmt.Errorf("master auth failed: %s", err)
+		}
+	} else if s.opts.Password != "" {
+		if _, err := redisb.Do(c, "AUTH", s.opts.Password); err != nil {
+			c.Close()
+			return nil, fmt.Errorf("master auth failed: %s", err)
+		}
+	}
+	if s.opts.DB != 0 {
+		if _, err := redisb.Do(c, "SELECT", strconv.Itoa(s.opts.DB)); err != nil {
+			c.Close()
+			return nil, fmt.Errorf("master select failed: %s", err)
+		}
+	}
+	return c, nil
 }
 
 func (s *SPool) log(msgs ...interface{}) {
@@ -122,14 +424,17 @@ func (s *SPool) log(msgs ...interface{}) {
 }
 
 func (s *SPool) bootstrap() {
-	s.State = Bootstrapping
+	s.setState(Bootstrapping)
+	seed := s.getHps()[0]
+	s.logger.Info("bootstrapping", "seed", seed)
 	defer func() {
 		if err := recover(); err != nil {
 			panic(fmt.Sprintf("failed to bootstrap: %s", err))
 		}
 	}()
-	c, err := net.Dial("tcp", s.hps[0])
+	c, err := s.dial(seed, true)
 	if err != nil {
+		s.logger.Error("bootstrap dial failed", "seed", seed, "err", err)
 		panic(err)
 	}
 	defer c.Close()
@@ -139,30 +444,100 @@ func (s *SPool) bootstrap() {
 	}
 	r := tmpr.([]interface{})
 	if strings.ToUpper(r[0].(string)) != "SENTINEL" {
-		panic(fmt.Sprintf("the given host:port, '%s', failed to respond correctly to a ROLE request. The given host:port must identify itself as having the sentinel role", s.hps[0]))
+		panic(fmt.Sprintf("the given host:port, '%s', failed to respond correctly to a ROLE request. The given host:port must identify itself as having the sentinel role", seed))
 	}
 	s.findPreferred()
 }
 
-func (s *SPool) findPreferred() {
-	if len(s.p) == 0 {
-		s.p = s.hps[0]
-	}
-	c, err := net.Dial("tcp", s.p)
+// sentinelProbe is the result of concurrently asking one known sentinel for
+// its view of the sentinel set for s.masterName.
+type sentinelProbe struct {
+	hp        string
+	latency   time.Duration
+	sentinels []string
+	err       error
+}
+
+func (s *SPool) probeSentinel(hp string) sentinelProbe {
+	start := time.Now()
+	c, err := s.dial(hp, true)
 	if err != nil {
-		panic(err)
+		s.logger.Warn("sentinel probe dial failed", "addr", hp, "err", err)
+		return sentinelProbe{hp: hp, err: err}
 	}
 	tmpr, err := redisb.Do(c, "SENTINEL", "sentinels", s.masterName)
 	c.Close()
 	if err != nil {
-		panic(fmt.Sprintf("Unable to get list of sentinels: %s", err))
+		s.logger.Warn("sentinel probe failed", "addr", hp, "err", err)
+		return sentinelProbe{hp: hp, err: err}
 	}
+	latency := time.Since(start)
+	s.logger.Debug("sentinel probe rtt", "addr", hp, "latency", latency)
 	tmpa := tmpr.([]interface{})
+	discovered := make([]string, 0, len(tmpa))
 	for _, tmpv := range tmpa {
 		v := tmpv.([]string)
-		h := v[3]
-		p := v[5]
-		hp := h + ":" + p
+		discovered = append(discovered, v[3]+":"+v[5])
+	}
+	return sentinelProbe{hp: hp, latency: latency, sentinels: discovered}
+}
+
+// findPreferred probes every known sentinel concurrently, preferring the
+// lowest-latency sentinel that actually answers `SENTINEL sentinels
+// <masterName>` over one that merely accepts a TCP connection. It also
+// folds newly discovered sentinels into s.hps and drops seeds that have
+// failed too many consecutive probes.
+func (s *SPool) findPreferred() {
+	s.coreMu.Lock()
+	if len(s.p) == 0 && len(s.hps) > 0 {
+		s.p = s.hps[0]
+	}
+	hps := append([]string{}, s.hps...)
+	s.coreMu.Unlock()
+
+	results := make(chan sentinelProbe, len(hps))
+	for _, hp := range hps {
+		go func(hp string) {
+			results <- s.probeSentinel(hp)
+		}(hp)
+	}
+
+	// Probes are collected without holding coreMu, since they block on
+	// network I/O; the merge back into s.p/s.hps/s.hpFails below is the
+	// only part that needs the lock.
+	var fastestHp string
+	fastest := time.Duration(1<<63 - 1)
+	discovered := map[string]bool{}
+	failed := map[string]int{}
+	succeeded := map[string]bool{}
+	for i := 0; i < len(hps); i++ {
+		r := <-results
+		if r.err != nil {
+			s.log("Sentinel probe failed:", r.hp, r.err)
+			failed[r.hp]++
+			continue
+		}
+		succeeded[r.hp] = true
+		if r.latency < fastest {
+			fastest = r.latency
+			fastestHp = r.hp
+		}
+		for _, hp := range r.sentinels {
+			discovered[hp] = true
+		}
+	}
+
+	s.coreMu.Lock()
+	for hp := range failed {
+		s.hpFails[hp] += failed[hp]
+	}
+	for hp := range succeeded {
+		s.hpFails[hp] = 0
+	}
+	if fastestHp != "" {
+		s.p = fastestHp
+	}
+	for hp := range discovered {
 		exists := false
 		for _, existing := range s.hps {
 			if existing == hp {
@@ -174,127 +549,762 @@ func (s *SPool) findPreferred() {
 			s.hps = append(s.hps, hp)
 		}
 	}
-	fastest := 1 * time.Second
-	for _, fhp := range s.hps {
-                start := time.Now()
-		c, err := net.DialTimeout("tcp", fhp, 100 * time.Millisecond)
-		if err != nil {
-                        continue
+	s.coreMu.Unlock()
+	s.pruneFailedSentinels()
+}
+
+// pruneFailedSentinels drops sentinels that have reached
+// Options.SentinelFailThreshold consecutive probe failures, so a handful of
+// stale or decommissioned seeds can't keep poisoning the rotation. s.p is
+// never pruned here; a failing preferred sentinel is replaced on the next
+// successful findPreferred instead.
+func (s *SPool) pruneFailedSentinels() {
+	threshold := s.opts.SentinelFailThreshold
+	if threshold <= 0 {
+		threshold = defaultSentinelFailThreshold
+	}
+	s.coreMu.Lock()
+	defer s.coreMu.Unlock()
+	survivors := make([]string, 0, len(s.hps))
+	for _, hp := range s.hps {
+		if hp != s.p && s.hpFails[hp] >= threshold {
+			s.log("Dropping sentinel after repeated failures:", hp)
+			delete(s.hpFails, hp)
+			continue
 		}
-                d := time.Since(start)
-                c.Close()
-                if d < fastest {
-                        s.p = fhp
-                        fastest = d
-                }
+		survivors = append(survivors, hp)
+	}
+	if len(survivors) > 0 {
+		s.hps = survivors
 	}
 }
 
+// reset recovers from a failover with no cancellation path: it blocks until
+// a master is found, or until Options.MaxResetAttempts (when > 0) is
+// exceeded, in which case the error is only logged. See resetCtx for the
+// context-aware version.
 func (s *SPool) reset() {
-	if s.State == Resetting {
-		return
+	if err := s.resetCtx(context.Background(), ""); err != nil {
+		s.log("reset failed:", err)
 	}
-	s.State = Resetting
+}
+
+// resetFromSwitchMaster is like reset, but candidate is the address a
+// +switch-master event just announced, letting resetCtx verify it with
+// ROLE MASTER directly instead of polling the sentinel for it.
+func (s *SPool) resetFromSwitchMaster(candidate string) {
+	if err := s.resetCtx(context.Background(), candidate); err != nil {
+		s.log("reset failed:", err)
+	}
+}
+
+// resetCtx recovers from a failover by polling the preferred sentinel for
+// the current master until one answers ROLE MASTER, then rebuilds s.pool.
+// If candidate is non-empty (a +switch-master payload verified it was the
+// trigger), resetCtx verifies it with ROLE MASTER directly and, on
+// success, skips the SENTINEL get-master-addr-by-name round trip; any
+// other reset trigger passes candidate as "" since s.master there is just
+// the address being failed away from, not a fresh announcement. resetCtx
+// aborts early with ErrResetCanceled if ctx is done, or with
+// ErrMaxResetAttempts once Options.MaxResetAttempts iterations have passed
+// without success, so a stuck recovery can't block the process forever.
+func (s *SPool) resetCtx(ctx context.Context, candidate string) error {
+	if !s.tryBeginReset() {
+		return nil
+	}
+	start := time.Now()
+	s.metrics.SetState(string(Resetting))
+	s.coreMu.Lock()
 	if s.pool == nil && len(s.master) > 0 {
 		s.pool = redisn.New(redisp.New(s.size, s.creator, s.retryDelay))
 	}
-	if s.pool != nil {
-		s.pool.Empty()
-	}
+	pool := s.pool
 	s.master = ""
-	for {
-		time.Sleep(s.resyncDelay)
-		c, err := net.Dial("tcp", s.p)
+	s.coreMu.Unlock()
+	if pool != nil {
+		pool.Empty()
+	}
+	attempts := 0
+	if len(candidate) > 0 && s.verifyMaster(ctx, candidate) {
+		s.log("Master verified from switch-master payload, skipping sentinel lookup:", candidate)
+		s.setMaster(candidate)
+	} else {
+		for {
+			select {
+			case <-ctx.Done():
+				s.metrics.ObserveSentinelResetDuration(time.Since(start))
+				return ErrResetCanceled
+			default:
+			}
+			attempts++
+			if s.opts.MaxResetAttempts > 0 && attempts > s.opts.MaxResetAttempts {
+				s.metrics.ObserveSentinelResetDuration(time.Since(start))
+				s.logger.Error("reset exceeded max attempts", "attempts", attempts, "masterName", s.masterName)
+				return ErrMaxResetAttempts
+			}
+			time.Sleep(s.resyncDelay)
+			c, err := s.dialCtx(ctx, s.getPreferred(), true)
+			if err != nil {
+				s.log("Failed to dial sentinel")
+				s.findPreferred()
+				continue
+			}
+			tmpr, err := redisb.Do(c, "SENTINEL", "get-master-addr-by-name", s.masterName)
+			if err != nil {
+				s.log("error getting master-addr:", err)
+				c.Close()
+				s.findPreferred()
+				continue
+			}
+			c.Close()
+			r := tmpr.([]interface{})
+			host := r[0].(string)
+			port := r[1].(string)
+			if net.ParseIP(host).To4() == nil {
+				host = "[" + host + "]"
+			}
+			maddr := fmt.Sprintf("%s:%s", host, port)
+			if !s.verifyMaster(ctx, maddr) {
+				s.log("maddr ROLE is not MASTER:", maddr)
+				continue
+			}
+			s.log("Master found:", maddr)
+			s.setMaster(maddr)
+			break
+		}
+	}
+	newPool := redisn.New(redisp.New(s.size, s.creator, s.retryDelay))
+	newPool.Fill()
+	s.coreMu.Lock()
+	s.pool = newPool
+	s.coreMu.Unlock()
+	s.discoverReplicas()
+	s.setState(Healthy)
+	s.metrics.ObserveSentinelResetDuration(time.Since(start))
+	s.logger.Info("reset complete", "master", s.getMaster(), "attempts", attempts)
+	s.resubscribe()
+	return nil
+}
+
+// verifyMaster dials addr directly and confirms it still answers ROLE
+// MASTER, letting resetCtx skip the SENTINEL get-master-addr-by-name round
+// trip when a candidate address (e.g. from a +switch-master payload) is
+// already known.
+func (s *SPool) verifyMaster(ctx context.Context, addr string) bool {
+	c, err := s.dialCtx(ctx, addr, false)
+	if err != nil {
+		return false
+	}
+	defer c.Close()
+	tmpr, err := redisb.Do(c, "ROLE")
+	if err != nil {
+		return false
+	}
+	r := tmpr.([]interface{})
+	return strings.ToUpper(r[0].(string)) == "MASTER"
+}
+
+// discoverReplicas asks the preferred sentinel for the replica set of
+// s.masterName and reconciles s.replicaPools to match: new replicas get a
+// pool, replicas that are no longer reported are dropped.
+func (s *SPool) discoverReplicas() {
+	c, err := s.dial(s.getPreferred(), true)
+	if err != nil {
+		s.log("Failed to dial sentinel for replicas:", err)
+		return
+	}
+	tmpr, err := redisb.Do(c, "SENTINEL", "replicas", s.masterName)
+	c.Close()
+	if err != nil {
+		s.log("Failed to get replicas:", err)
+		return
+	}
+	tmpa := tmpr.([]interface{})
+	addrs := make([]string, 0, len(tmpa))
+	for _, tmpv := range tmpa {
+		v := tmpv.([]string)
+		addrs = append(addrs, v[3]+":"+v[5])
+	}
+	s.syncReplicaPools(addrs)
+}
+
+// syncReplicaPools reconciles s.replicaPools against the given set of
+// currently known-good replica addresses.
+func (s *SPool) syncReplicaPools(addrs []string) {
+	s.replicaMu.Lock()
+	defer s.replicaMu.Unlock()
+	wanted := map[string]bool{}
+	for _, addr := range addrs {
+		wanted[addr] = true
+		if _, exists := s.replicaPools[addr]; !exists {
+			s.addReplicaPoolLocked(addr)
+		}
+	}
+	for addr, pool := range s.replicaPools {
+		if !wanted[addr] {
+			pool.Empty()
+			delete(s.replicaPools, addr)
+		}
+	}
+	s.replicas = addrs
+}
+
+// replicaDialError is what addReplicaPoolLocked's creator panics with on a
+// final dial failure, since redisp.Creator has no error return.
+// getFromReplicaPool/pdoFromReplicaPool recover only this type and let any
+// other panic propagate, so a bug elsewhere in the pool doesn't get
+// silently downgraded to a routine "replica unavailable" fallback.
+type replicaDialError struct {
+	addr string
+	err  error
+}
+
+func (e *replicaDialError) Error() string {
+	return fmt.Sprintf("failed to dial replica '%s': '%s'", e.addr, e.err)
+}
+
+// addReplicaPoolLocked creates a pool for addr. Callers must hold
+// s.replicaMu.
+func (s *SPool) addReplicaPoolLocked(addr string) {
+	creator := func() net.Conn {
+		c, err := s.dial(addr, false)
 		if err != nil {
-			s.log("Failed to dial sentinel")
-			s.findPreferred()
-			continue
+			if strings.HasSuffix(err.Error(), "connection refused") {
+				s.log("Connection error with replica at addr: '%s'", addr)
+				s.logger.Warn("replica dial refused, retrying", "replica", addr)
+				c, err = s.dial(addr, false)
+			}
+			if err != nil {
+				s.metrics.IncPoolDialError()
+				s.logger.Warn("replica dial failed, evicting", "replica", addr, "err", err)
+				go s.evictReplica(addr)
+				panic(&replicaDialError{addr: addr, err: err})
+			}
 		}
-		tmpr, err := redisb.Do(c, "SENTINEL", "get-master-addr-by-name", s.masterName)
+		return c
+	}
+	pool := redisn.New(redisp.New(s.size, creator, s.retryDelay))
+	pool.Fill()
+	if s.replicaPools == nil {
+		s.replicaPools = map[string]*redisn.NPool{}
+	}
+	s.replicaPools[addr] = pool
+}
+
+// evictReplica drops the pool for addr, e.g. after a sentinel +sdown event
+// against that replica.
+func (s *SPool) evictReplica(addr string) {
+	s.replicaMu.Lock()
+	defer s.replicaMu.Unlock()
+	if pool, ok := s.replicaPools[addr]; ok {
+		pool.Empty()
+		delete(s.replicaPools, addr)
+	}
+	survivors := make([]string, 0, len(s.replicas))
+	for _, r := range s.replicas {
+		if r != addr {
+			survivors = append(survivors, r)
+		}
+	}
+	s.replicas = survivors
+	s.log("Evicted replica:", addr)
+}
+
+// restoreReplica re-adds a pool for addr, e.g. after a sentinel -sdown
+// event against that replica.
+func (s *SPool) restoreReplica(addr string) {
+	s.replicaMu.Lock()
+	defer s.replicaMu.Unlock()
+	if _, ok := s.replicaPools[addr]; ok {
+		return
+	}
+	s.addReplicaPoolLocked(addr)
+	s.replicas = append(s.replicas, addr)
+	s.log("Restored replica:", addr)
+}
+
+// replicaPool picks the next replica pool in round-robin order, or nil if
+// there are no healthy replicas.
+func (s *SPool) replicaPool() *redisn.NPool {
+	s.replicaMu.Lock()
+	defer s.replicaMu.Unlock()
+	if len(s.replicas) == 0 {
+		return nil
+	}
+	addr := s.replicas[s.replicaIdx%uint64(len(s.replicas))]
+	s.replicaIdx++
+	return s.replicaPools[addr]
+}
+
+// GetReplica borrows a connection from the next replica pool in round-robin
+// order, falling back to the master pool when there are no healthy
+// replicas or the chosen replica's pool can't dial (see
+// addReplicaPoolLocked's creator).
+func (s *SPool) GetReplica() net.Conn {
+	pool := s.replicaPool()
+	if pool == nil {
+		return s.Get()
+	}
+	c, err := s.getFromReplicaPool(pool)
+	if err != nil {
+		s.logger.Warn("replica unavailable, falling back to master", "err", err)
+		return s.Get()
+	}
+	s.replicaMu.Lock()
+	s.replicaConnOwner[c] = pool
+	s.replicaMu.Unlock()
+	return c
+}
+
+// getFromReplicaPool borrows a connection from pool, recovering from a
+// dial panic raised by the replica creator (see addReplicaPoolLocked) and
+// reporting it as an error instead of letting it crash the process.
+func (s *SPool) getFromReplicaPool(pool *redisn.NPool) (c net.Conn, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			dialErr, ok := r.(*replicaDialError)
+			if !ok {
+				panic(r)
+			}
+			err = dialErr
+		}
+	}()
+	c = pool.Get()
+	return c, nil
+}
+
+// PutReplica returns a connection borrowed from GetReplica to whichever
+// replica pool vended it, or to the master pool if it came from the
+// fallback path. Callers that observed an error on c should call
+// BadReplica instead, mirroring the Get/Put/Bad convention used for the
+// master pool.
+func (s *SPool) PutReplica(c net.Conn) {
+	s.replicaMu.Lock()
+	pool, ok := s.replicaConnOwner[c]
+	delete(s.replicaConnOwner, c)
+	s.replicaMu.Unlock()
+	if !ok {
+		s.Put(c)
+		return
+	}
+	pool.Put(c)
+}
+
+// BadReplica reports a connection borrowed from GetReplica as broken, so
+// its pool can discard and replace it rather than returning it to rotation.
+func (s *SPool) BadReplica(c net.Conn) {
+	s.replicaMu.Lock()
+	pool, ok := s.replicaConnOwner[c]
+	delete(s.replicaConnOwner, c)
+	s.replicaMu.Unlock()
+	if !ok {
+		s.Bad(c)
+		return
+	}
+	pool.Bad(c)
+}
+
+// PDoReplica runs args against the next replica pool in round-robin order,
+// falling back to the master pool when there are no healthy replicas or the
+// chosen replica's pool can't dial (see addReplicaPoolLocked's creator).
+func (s *SPool) PDoReplica(args ...string) (interface{}, error) {
+	pool := s.replicaPool()
+	if pool == nil {
+		return s.PDo(args...)
+	}
+	v, err := s.pdoFromReplicaPool(pool, args...)
+	if err != nil {
+		s.logger.Warn("replica unavailable, falling back to master", "err", err)
+		return s.PDo(args...)
+	}
+	s.recordCommand(err)
+	return v, err
+}
+
+// pdoFromReplicaPool runs args against pool, recovering from a dial panic
+// raised by the replica creator (see addReplicaPoolLocked) and reporting it
+// as an error instead of letting it crash the process.
+func (s *SPool) pdoFromReplicaPool(pool *redisn.NPool, args ...string) (v interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			dialErr, ok := r.(*replicaDialError)
+			if !ok {
+				panic(r)
+			}
+			err = dialErr
+		}
+	}()
+	v, err = pool.PDo(args...)
+	return v, err
+}
+
+// readOnlyCommands is the curated set of commands Call routes to a replica
+// when Options.RouteByCommand is set. It's deliberately conservative:
+// anything not listed is treated as a write and goes to the master.
+var readOnlyCommands = map[string]bool{
+	"GET":       true,
+	"MGET":      true,
+	"STRLEN":    true,
+	"GETRANGE":  true,
+	"HGET":      true,
+	"HMGET":     true,
+	"HGETALL":   true,
+	"HKEYS":     true,
+	"HVALS":     true,
+	"HLEN":      true,
+	"SMEMBERS":  true,
+	"SISMEMBER": true,
+	"SCARD":     true,
+	"ZRANGE":    true,
+	"ZREVRANGE": true,
+	"ZSCORE":    true,
+	"ZCARD":     true,
+	"LRANGE":    true,
+	"LINDEX":    true,
+	"LLEN":      true,
+	"EXISTS":    true,
+	"TTL":       true,
+	"PTTL":      true,
+	"TYPE":      true,
+}
+
+// Call is the routing entrypoint: when Options.RouteByCommand is set and
+// args names a command in readOnlyCommands, it's sent to a replica via
+// PDoReplica; everything else goes to the master via PDo.
+func (s *SPool) Call(args ...string) (interface{}, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("rediss: Call requires a command")
+	}
+	if s.opts.RouteByCommand && readOnlyCommands[strings.ToUpper(args[0])] {
+		return s.PDoReplica(args...)
+	}
+	return s.PDo(args...)
+}
+
+// sentinelEventChannels are the sentinel pubsub channels that can signal a
+// state change for s.masterName. See https://redis.io/docs/manual/sentinel/#pubsub-messages
+var sentinelEventChannels = []string{
+	"+switch-master",
+	"+odown",
+	"-odown",
+	"+sdown",
+	"-sdown",
+	"+reset-master",
+}
+
+const minSentinelEventBackoff = 100 * time.Millisecond
+const maxSentinelEventBackoff = 30 * time.Second
+
+// sentinelSubscriber is a minimal, single-connection RESP subscriber good
+// enough for sentinel's pubsub messages. redisn only exports pool-oriented
+// helpers (Handler, NDo, NUnDo, New, NPool) and has nothing standalone for a
+// raw SUBSCRIBE/message loop over one conn, so SPool owns this small bit of
+// protocol handling itself rather than routing sentinel events through a
+// pool for a single dedicated connection.
+type sentinelSubscriber struct {
+	conn net.Conn
+	r    *bufio.Reader
+	done chan struct{}
+}
+
+func newSentinelSubscriber(c net.Conn) *sentinelSubscriber {
+	return &sentinelSubscriber{conn: c, r: bufio.NewReader(c), done: make(chan struct{})}
+}
+
+// subscribe sends a single SUBSCRIBE for channels, reads their confirmation
+// replies, and starts a goroutine delivering "message" frames to handler
+// until the connection is closed or fails.
+func (sub *sentinelSubscriber) subscribe(handler func(channel, msg string), channels ...string) error {
+	if err := sub.writeSubscribe(channels); err != nil {
+		return err
+	}
+	for range channels {
+		if _, err := sub.readArray(); err != nil {
+			return err
+		}
+	}
+	go sub.readLoop(handler)
+	return nil
+}
+
+func (sub *sentinelSubscriber) writeSubscribe(channels []string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n$9\r\nSUBSCRIBE\r\n", len(channels)+1)
+	for _, c := range channels {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(c), c)
+	}
+	_, err := sub.conn.Write([]byte(b.String()))
+	return err
+}
+
+// readArray reads one RESP array whose elements are bulk strings or RESP
+// integers, which covers the frames sentinel's pubsub sends (subscribe
+// confirmations and messages alike). Integer elements are returned as
+// their decimal text.
+func (sub *sentinelSubscriber) readArray() ([]string, error) {
+	line, err := sub.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if len(line) == 0 || line[0] != '*' {
+		return nil, fmt.Errorf("rediss: unexpected sentinel pubsub frame: %q", line)
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, n)
+	for i := 0; i < n; i++ {
+		head, err := sub.r.ReadString('\n')
 		if err != nil {
-			s.log("error getting master-addr:", err)
-			c.Close()
-			s.findPreferred()
-			continue
+			return nil, err
 		}
-		c.Close()
-		r := tmpr.([]interface{})
-		host := r[0].(string)
-		port := r[1].(string)
-		if net.ParseIP(host).To4() == nil {
-			host = "[" + host + "]"
+		head = strings.TrimRight(head, "\r\n")
+		if len(head) == 0 {
+			return nil, fmt.Errorf("rediss: unexpected sentinel pubsub element header: %q", head)
+		}
+		switch head[0] {
+		case '$':
+			size, err := strconv.Atoi(head[1:])
+			if err != nil {
+				return nil, err
+			}
+			buf := make([]byte, size+2) // +2 for the trailing \r\n
+			if _, err := io.ReadFull(sub.r, buf); err != nil {
+				return nil, err
+			}
+			out[i] = string(buf[:size])
+		case ':':
+			// The SUBSCRIBE confirmation reply's third element is the
+			// subscribed-channel count as a RESP integer, not a bulk
+			// string.
+			out[i] = head[1:]
+		default:
+			return nil, fmt.Errorf("rediss: unexpected sentinel pubsub element header: %q", head)
 		}
-		maddr := fmt.Sprintf("%s:%s", host, port)
-		c, err = net.Dial("tcp", maddr)
+	}
+	return out, nil
+}
+
+func (sub *sentinelSubscriber) readLoop(handler func(channel, msg string)) {
+	defer close(sub.done)
+	for {
+		frame, err := sub.readArray()
 		if err != nil {
-			s.log("Failed to dial master:", maddr, err)
+			return
+		}
+		if len(frame) != 3 || frame[0] != "message" {
 			continue
 		}
-		tmpr, err = redisb.Do(c, "ROLE")
+		handler(frame[1], frame[2])
+	}
+}
+
+// wait blocks until the subscription's connection drops or is closed.
+func (sub *sentinelSubscriber) wait() {
+	<-sub.done
+}
+
+func (sub *sentinelSubscriber) close() {
+	sub.conn.Close()
+}
+
+// pubSub subscribes to sentinel events on a dedicated connection so SPool
+// learns about failovers as they happen, instead of only discovering them
+// lazily when a command against the master fails. It runs for the lifetime
+// of the SPool, reconnecting with exponential backoff whenever the
+// subscription drops.
+func (s *SPool) pubSub() {
+	go s.subscribeSentinelEvents()
+}
+
+func (s *SPool) subscribeSentinelEvents() {
+	backoff := s.resyncDelay
+	if backoff < minSentinelEventBackoff {
+		backoff = minSentinelEventBackoff
+	}
+	for {
+		addr := s.getPreferred()
+		if len(addr) == 0 {
+			if hps := s.getHps(); len(hps) > 0 {
+				addr = hps[0]
+			}
+		}
+		if len(addr) == 0 {
+			time.Sleep(backoff)
+			continue
+		}
+		c, err := s.dial(addr, true)
 		if err != nil {
-			s.log("Failed to get maddr ROLE:", err)
-			c.Close()
+			s.log("pubSub: failed to dial sentinel for events:", addr, err)
+			time.Sleep(backoff)
+			backoff = nextSentinelEventBackoff(backoff)
 			continue
 		}
-		c.Close()
-		r = tmpr.([]interface{})
-		if strings.ToUpper(r[0].(string)) != "MASTER" {
-			s.log("maddr ROLE is not MASTER:", r[0].(string))
+		sub := newSentinelSubscriber(c)
+		s.subMu.Lock()
+		s.sub = sub
+		s.subMu.Unlock()
+		if err := sub.subscribe(s.handleSentinelEvent, sentinelEventChannels...); err != nil {
+			s.log("pubSub: subscribe failed:", addr, err)
+			c.Close()
+			time.Sleep(backoff)
+			backoff = nextSentinelEventBackoff(backoff)
 			continue
 		}
-		s.log("Master found:", maddr)
-		s.master = maddr
-		break
+		backoff = s.resyncDelay
+		if backoff < minSentinelEventBackoff {
+			backoff = minSentinelEventBackoff
+		}
+		sub.wait() // blocks until the subscriber's connection drops or is closed
+		s.log("pubSub: sentinel event subscription ended, reconnecting:", addr)
 	}
-	s.pool = redisn.New(redisp.New(s.size, s.creator, s.retryDelay))
-	s.pool.Fill()
-	s.State = Healthy
-	s.resubscribe()
 }
 
-func (s *SPool) pubSub() {
-	// TODO - this needs refactoring of the redisn package to allow for pubsub w/out a pool
-	// ... or we create a pool for the sentinels... a pool of size one... such a waste
-	/*
-		isMasterName := func(msg string) bool {
-			tmp := strings.SplitN(msg, " @ ", 2)
-			if len(tmp) < 2 {
-				s.log("Incorrectly formatted Sentinel pubsub message:", msg)
-				return false
-			}
-			msga := strings.SplitN(tmp[1], " ", 2)
-			s.log("Sentinel pubsub:", msg)
-			if len(msga) < 2 {
-				s.log("Incorrectly formatted Sentinel pubsub message:", msg)
-				return false
-			}
-			s.log("Message from masterName")
-			return msga[0] == s.masterName
+func nextSentinelEventBackoff(cur time.Duration) time.Duration {
+	next := cur * 2
+	if next <= 0 || next > maxSentinelEventBackoff {
+		return maxSentinelEventBackoff
+	}
+	return next
+}
+
+// handleSentinelEvent is invoked by the sentinel subscriber for every
+// message on sentinelEventChannels. +switch-master carries the new master
+// address directly (`<master-name> <old-ip> <old-port> <new-ip> <new-port>`),
+// so s.master is updated from the payload and the address is passed to
+// resetFromSwitchMaster, which has resetCtx verify it with ROLE MASTER
+// directly, skipping the get-master-addr-by-name round trip when that
+// check succeeds. A reset triggered by any other event (e.g. +odown on
+// the master) goes through plain reset, which always falls back to the
+// full sentinel lookup, since s.master there is just the address being
+// failed away from, not a fresh announcement. The +/- odown and sdown
+// channels report on
+// whichever instance sentinel is monitoring: the master itself is reported
+// with no `@` (`master <name> <ip> <port>`), while a monitored replica or
+// peer sentinel is reported as `<instance-type> <name> <ip> <port> @
+// <master-name> <master-ip> <master-port>`. Only a master-type event flips
+// s.up and triggers a reset; a sentinel-type event is otherwise ignored.
+func (s *SPool) handleSentinelEvent(channel string, msg string) {
+	s.log("Sentinel event:", channel, msg)
+	switch channel {
+	case "+switch-master":
+		fields := strings.Fields(msg)
+		if len(fields) != 5 || fields[0] != s.masterName {
+			return
 		}
-		s.Do(c, "SUBSCRIBE", func(k string, msg string, err error) {
-			if isMasterName(msg) {
-				s.up = false
-			}
-		}, "+odown")
-		s.Do(c, "SUBSCRIBE", func(k string, msg string, err error) {
-			if isMasterName(msg) {
-				s.up = true
+		host := fields[3]
+		port := fields[4]
+		if net.ParseIP(host).To4() == nil {
+			host = "[" + host + "]"
+		}
+		newMaster := host + ":" + port
+		s.setMaster(newMaster)
+		s.setUp(true)
+		s.metrics.IncSentinelFailoverTotal()
+		s.logger.Warn("sentinel reported switch-master", "masterName", s.masterName, "master", newMaster)
+		go s.resetFromSwitchMaster(newMaster)
+	case "+odown", "+sdown":
+		instanceType, ip, port, matches := s.parseSentinelEvent(msg)
+		if !matches {
+			return
+		}
+		switch instanceType {
+		case "master":
+			s.setUp(false)
+			go s.reset()
+		case "slave", "replica":
+			if ip != "" && port != "" {
+				s.evictReplica(ip + ":" + port)
 			}
-		}, "-odown")
-		s.Do(c, "SUBSCRIBE", func(k string, msg string, err error) {
-			if isMasterName(msg) {
-				s.up = true
+		}
+	case "-odown", "-sdown":
+		instanceType, ip, port, matches := s.parseSentinelEvent(msg)
+		if !matches {
+			return
+		}
+		switch instanceType {
+		case "master":
+			s.setUp(true)
+		case "slave", "replica":
+			if ip != "" && port != "" {
+				s.restoreReplica(ip + ":" + port)
 			}
-		}, "switch-master")
-	*/
+		}
+	case "+reset-master":
+		if !s.sentinelEventMatchesMaster(msg) {
+			return
+		}
+		go s.reset()
+	}
+}
+
+// sentinelEventMatchesMaster reports whether msg, in either the master or
+// `@`-delimited instance form parsed by parseSentinelEvent, concerns
+// s.masterName.
+func (s *SPool) sentinelEventMatchesMaster(msg string) bool {
+	_, _, _, matches := s.parseSentinelEvent(msg)
+	return matches
+}
+
+// parseSentinelEvent parses a sentinel pubsub instance description in
+// either of the two forms sentinel emits on the odown/sdown/reset-master
+// channels: the master itself is reported as `master <name> <ip> <port>`
+// with no `@`, while a monitored replica or peer sentinel is reported as
+// `<instance-type> <name> <ip> <port> @ <master-name> <master-ip>
+// <master-port>`. It returns the reporting instance's type and address and
+// whether the event concerns s.masterName.
+func (s *SPool) parseSentinelEvent(msg string) (instanceType, ip, port string, matches bool) {
+	parts := strings.SplitN(msg, " @ ", 2)
+	if len(parts) < 2 {
+		fields := strings.Fields(msg)
+		if len(fields) < 4 || fields[0] != "master" {
+			s.log("Incorrectly formatted Sentinel pubsub message:", msg)
+			return "", "", "", false
+		}
+		return "master", fields[2], fields[3], fields[1] == s.masterName
+	}
+	pre := strings.Fields(parts[0])
+	post := strings.Fields(parts[1])
+	if len(pre) == 0 || len(post) == 0 {
+		s.log("Incorrectly formatted Sentinel pubsub message:", msg)
+		return "", "", "", false
+	}
+	instanceType = pre[0]
+	if len(pre) >= 4 {
+		ip = pre[2]
+		port = pre[3]
+	}
+	return instanceType, ip, port, post[0] == s.masterName
+}
+
+// applyCommandTimeouts honors Options.ReadTimeout/WriteTimeout on a
+// command's conn. Only call sites that hold the conn directly (as opposed
+// to PDo/NDo, where the conn is internal to redisn.NPool) can apply these.
+func (s *SPool) applyCommandTimeouts(c net.Conn) {
+	if s.opts.WriteTimeout > 0 {
+		c.SetWriteDeadline(time.Now().Add(s.opts.WriteTimeout))
+	}
+	if s.opts.ReadTimeout > 0 {
+		c.SetReadDeadline(time.Now().Add(s.opts.ReadTimeout))
+	}
 }
 
 func (s *SPool) Do(c net.Conn, args ...string) (interface{}, error) {
-	return redisb.Do(s.Get(), args...)
+	conn := s.Get()
+	s.applyCommandTimeouts(conn)
+	v, err := redisb.Do(conn, args...)
+	s.recordCommand(err)
+	return v, err
 }
 
 func (s *SPool) DoN(c net.Conn, args ...string) (interface{}, error) {
-	return redisb.DoN(s.Get(), args...)
+	conn := s.Get()
+	s.applyCommandTimeouts(conn)
+	v, err := redisb.DoN(conn, args...)
+	s.recordCommand(err)
+	return v, err
 }
 
 func (s *SPool) Out(c net.Conn, args ...string) {
@@ -302,28 +1312,35 @@ func (s *SPool) Out(c net.Conn, args ...string) {
 }
 
 func (s *SPool) Get() net.Conn {
-	return s.pool.Get()
+	start := time.Now()
+	c := s.getPool().Get()
+	s.metrics.ObservePoolAcquireWait(time.Since(start))
+	return c
 }
 
 func (s *SPool) Put(c net.Conn) {
-	if s.up {
-		s.pool.Put(c)
+	if s.isUp() {
+		s.getPool().Put(c)
 	} else {
-		s.pool.Bad(c)
+		s.getPool().Bad(c)
 	}
 }
 
 func (s *SPool) Bad(c net.Conn) {
-	s.pool.Bad(c)
+	s.getPool().Bad(c)
 }
 
 func (s *SPool) PDo(args ...string) (interface{}, error) {
-	return s.pool.PDo(args...)
+	v, err := s.getPool().PDo(args...)
+	s.recordCommand(err)
+	return v, err
 }
 
 func (s *SPool) NDo(command string, handler redisn.Handler, keys ...string) error {
-	err := s.pool.NDo(command, handler, keys...)
+	err := s.getPool().NDo(command, handler, keys...)
+	s.recordCommand(err)
 	if err == nil {
+		s.nMu.Lock()
 		for _, k := range keys {
 			ck := command + ":" + k
 			_, exists := s.n[ck]
@@ -332,26 +1349,171 @@ func (s *SPool) NDo(command string, handler redisn.Handler, keys ...string) erro
 			}
 			s.n[ck] = append(s.n[ck], handler)
 		}
+		s.nMu.Unlock()
 	}
 	return err
 }
 
 func (s *SPool) resubscribe() {
+	s.nMu.Lock()
+	n := make(map[string][]redisn.Handler, len(s.n))
 	for ck, hs := range s.n {
+		n[ck] = hs
+	}
+	s.nMu.Unlock()
+	for ck, hs := range n {
 		tmp := strings.SplitN(ck, ":", 2)
 		c := tmp[0]
 		k := tmp[1]
 		for _, h := range hs {
-			s.NDo(c, h, k) // Ignoring errors - there's no useful caller to handle any
+			// Re-registering against the pool, not s.NDo: the handler is
+			// already tracked in s.n, and going through s.NDo here would
+			// re-append it on every resubscribe, duplicating it.
+			s.getPool().NDo(c, h, k) // Ignoring errors - there's no useful caller to handle any
 		}
 	}
+	s.restartSentinelSubscriber()
+}
+
+// restartSentinelSubscriber closes the current sentinel event subscriber,
+// if any, so subscribeSentinelEvents redials against the (possibly new)
+// preferred sentinel in s.p instead of continuing to listen through a
+// stale one.
+func (s *SPool) restartSentinelSubscriber() {
+	s.subMu.Lock()
+	sub := s.sub
+	s.subMu.Unlock()
+	if sub != nil {
+		sub.close()
+	}
+}
+
+// runWithCtx bounds a blocking pool operation by ctx. redisb and redisn are
+// synchronous with no native cancellation, so fn keeps running in its
+// goroutine even after ctx is done; this only unblocks the caller early
+// and reports ctx.Err() instead of waiting for fn to finish.
+func runWithCtx(ctx context.Context, fn func() (interface{}, error)) (interface{}, error) {
+	type result struct {
+		v   interface{}
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		v, err := fn()
+		ch <- result{v, err}
+	}()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-ch:
+		return r.v, r.err
+	}
+}
+
+// runWithConnCtx runs fn, which operates on c, and makes ctx cancellation
+// actually unblock it: neither redisb nor redisn take a context, so the
+// only real lever is net.Conn's own deadline. A ctx deadline is applied to
+// c directly; a cancel-without-deadline context is additionally watched by
+// a goroutine that forces an immediate deadline the moment ctx is done, so
+// a blocked Read/Write on c returns instead of running forever. The
+// deadline is always cleared before returning so a conn that goes back to
+// the pool doesn't carry a stale one into its next use.
+func (s *SPool) runWithConnCtx(ctx context.Context, c net.Conn, fn func() (interface{}, error)) (interface{}, error) {
+	if dl, ok := ctx.Deadline(); ok {
+		c.SetDeadline(dl)
+	}
+	stop := make(chan struct{})
+	if d := ctx.Done(); d != nil {
+		go func() {
+			select {
+			case <-d:
+				c.SetDeadline(time.Now())
+			case <-stop:
+			}
+		}()
+	}
+	v, err := fn()
+	close(stop)
+	c.SetDeadline(time.Time{})
+	return v, err
+}
+
+// PDoCtx is PDo bounded by ctx: the pooled conn's deadline tracks ctx for
+// the duration of the round trip, instead of only detaching the caller
+// from a command that keeps running in the background after ctx is done.
+func (s *SPool) PDoCtx(ctx context.Context, args ...string) (interface{}, error) {
+	c, err := s.GetCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	v, err := s.runWithConnCtx(ctx, c, func() (interface{}, error) {
+		return redisb.Do(c, args...)
+	})
+	s.recordCommand(err)
+	if err != nil {
+		s.Bad(c)
+		return v, err
+	}
+	s.Put(c)
+	return v, err
+}
+
+// DoCtx is Do bounded by ctx, same as PDoCtx.
+func (s *SPool) DoCtx(ctx context.Context, c net.Conn, args ...string) (interface{}, error) {
+	gc, err := s.GetCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	v, err := s.runWithConnCtx(ctx, gc, func() (interface{}, error) {
+		return redisb.Do(gc, args...)
+	})
+	s.recordCommand(err)
+	if err != nil {
+		s.Bad(gc)
+		return v, err
+	}
+	s.Put(gc)
+	return v, err
+}
+
+// NDoCtx is NDo bounded by ctx. ctx only guards registering the
+// subscription; once registered it runs for the life of the pool like any
+// other NDo subscription.
+func (s *SPool) NDoCtx(ctx context.Context, command string, handler redisn.Handler, keys ...string) error {
+	_, err := runWithCtx(ctx, func() (interface{}, error) {
+		return nil, s.NDo(command, handler, keys...)
+	})
+	return err
+}
+
+// GetCtx is Get bounded by ctx. Get() keeps running even after ctx is
+// done, since the pool has no native cancellation; if ctx wins the race,
+// the connection Get() eventually returns is handed straight back to the
+// pool via Put instead of being silently dropped, so a canceled caller
+// can't leak a checked-out connection.
+func (s *SPool) GetCtx(ctx context.Context) (net.Conn, error) {
+	ch := make(chan net.Conn, 1)
+	go func() {
+		ch <- s.Get()
+	}()
+	select {
+	case <-ctx.Done():
+		go func() {
+			s.Put(<-ch)
+		}()
+		return nil, ctx.Err()
+	case c := <-ch:
+		return c, nil
+	}
 }
 
 func (s *SPool) NUnDo(command string, keys ...string) error {
-	err := s.pool.NUnDo(command, keys...)
+	err := s.getPool().NUnDo(command, keys...)
+	s.nMu.Lock()
 	for _, k := range keys {
 		ck := command + ":" + k
 		delete(s.n, ck)
 	}
+	s.nMu.Unlock()
 	return err
 }
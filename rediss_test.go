@@ -0,0 +1,75 @@
+package rediss
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSentinelEventMaster(t *testing.T) {
+	s := &SPool{masterName: "mymaster"}
+	instanceType, ip, port, matches := s.parseSentinelEvent("master mymaster 127.0.0.1 6379")
+	if !matches {
+		t.Fatalf("expected a no-@ master event for the watched master to match")
+	}
+	if instanceType != "master" {
+		t.Errorf("instanceType = %q, want %q", instanceType, "master")
+	}
+	if ip != "127.0.0.1" || port != "6379" {
+		t.Errorf("ip:port = %s:%s, want 127.0.0.1:6379", ip, port)
+	}
+}
+
+func TestParseSentinelEventMasterOtherName(t *testing.T) {
+	s := &SPool{masterName: "mymaster"}
+	_, _, _, matches := s.parseSentinelEvent("master othermaster 127.0.0.1 6379")
+	if matches {
+		t.Fatalf("expected a no-@ master event for a different master name to not match")
+	}
+}
+
+func TestParseSentinelEventReplica(t *testing.T) {
+	s := &SPool{masterName: "mymaster"}
+	instanceType, ip, port, matches := s.parseSentinelEvent("slave 127.0.0.1:6380 127.0.0.1 6380 @ mymaster 127.0.0.1 6379")
+	if !matches {
+		t.Fatalf("expected an @-delimited replica event for the watched master to match")
+	}
+	if instanceType != "slave" {
+		t.Errorf("instanceType = %q, want %q", instanceType, "slave")
+	}
+	if ip != "127.0.0.1" || port != "6380" {
+		t.Errorf("ip:port = %s:%s, want 127.0.0.1:6380", ip, port)
+	}
+}
+
+func TestParseSentinelEventSentinel(t *testing.T) {
+	s := &SPool{masterName: "mymaster"}
+	instanceType, _, _, matches := s.parseSentinelEvent("sentinel abcdef 127.0.0.1 26380 @ mymaster 127.0.0.1 6379")
+	if !matches {
+		t.Fatalf("expected an @-delimited sentinel event for the watched master to match")
+	}
+	if instanceType != "sentinel" {
+		t.Errorf("instanceType = %q, want %q", instanceType, "sentinel")
+	}
+}
+
+func TestParseSentinelEventMalformed(t *testing.T) {
+	s := &SPool{masterName: "mymaster"}
+	if _, _, _, matches := s.parseSentinelEvent("garbage"); matches {
+		t.Fatalf("expected a malformed event to not match")
+	}
+}
+
+func TestReadArraySubscribeConfirmation(t *testing.T) {
+	frame := "*3\r\n$9\r\nsubscribe\r\n$14\r\n+switch-master\r\n:1\r\n"
+	sub := &sentinelSubscriber{r: bufio.NewReader(strings.NewReader(frame))}
+	got, err := sub.readArray()
+	if err != nil {
+		t.Fatalf("readArray() error = %v", err)
+	}
+	want := []string{"subscribe", "+switch-master", "1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readArray() = %#v, want %#v", got, want)
+	}
+}